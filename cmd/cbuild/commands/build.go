@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2023 Arm Limited. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package commands
+
+import (
+	builder "cbuild/pkg/builder"
+	"cbuild/pkg/builder/csolution"
+	"cbuild/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// buildFlags holds the `build` command's own flag values.
+type buildFlags struct {
+	contexts []string
+	filter   string
+	outDir   string
+	intDir   string
+	packs    bool
+	jobs     int
+	failFast bool
+	noCache  bool
+	cacheDir string
+}
+
+// NewBuildCmd returns the `build <csolution.yml>` command, which builds
+// every selected context of a *.csolution.yml through csolution.CSolutionBuilder.
+func NewBuildCmd() *cobra.Command {
+	f := &buildFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "build <csolution.yml>",
+		Short: "Build contexts of a csolution",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configs, err := utils.GetInstallConfigs()
+			if err != nil {
+				return err
+			}
+
+			b := csolution.CSolutionBuilder{
+				BuilderParams: builder.BuilderParams{
+					Runner:         utils.CommandRunner{},
+					InputFile:      args[0],
+					InstallConfigs: configs,
+					Options: builder.Options{
+						Context:  f.contexts,
+						Filter:   f.filter,
+						OutDir:   f.outDir,
+						IntDir:   f.intDir,
+						Packs:    f.packs,
+						Jobs:     f.jobs,
+						FailFast: f.failFast,
+						NoCache:  f.noCache,
+						CacheDir: f.cacheDir,
+					},
+				},
+			}
+			return b.Build()
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&f.contexts, "context", "c", nil, "context name(s) to build")
+	cmd.Flags().StringVar(&f.filter, "filter", "", "filter the context list")
+	cmd.Flags().StringVar(&f.outDir, "outdir", "", "build output directory")
+	cmd.Flags().StringVar(&f.intDir, "intdir", "", "intermediate directory")
+	cmd.Flags().BoolVar(&f.packs, "packs", false, "install missing packs before building")
+	cmd.Flags().IntVarP(&f.jobs, "jobs", "j", 0, "number of contexts to build in parallel (default: number of CPUs)")
+	cmd.Flags().BoolVar(&f.failFast, "fail-fast", false, "stop dispatching new contexts as soon as one fails")
+	cmd.Flags().BoolVar(&f.noCache, "no-cache", false, "always rebuild, ignoring the build cache")
+	cmd.Flags().StringVar(&f.cacheDir, "cache-dir", "", "directory to store the build cache in (default: the --outdir directory, or the current directory without --outdir)")
+
+	return cmd
+}
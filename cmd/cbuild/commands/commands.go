@@ -0,0 +1,33 @@
+/*
+ * Copyright (c) 2023 Arm Limited. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package commands assembles the cbuild cobra command tree.
+package commands
+
+import (
+	"github.com/Open-CMSIS-Pack/cbuild/v2/cmd/cbuild/commands/build"
+	"github.com/spf13/cobra"
+)
+
+// NewRootCmd constructs the cbuild root command with every subcommand
+// wired in. Each invocation returns a fresh tree so tests can run
+// independent command lines without leaking flag state.
+func NewRootCmd() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:           "cbuild",
+		Short:         "Build a CMSIS-Toolbox csolution or CPRJ project",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	rootCmd.AddCommand(build.NewBuildCPRJCmd())
+	rootCmd.AddCommand(NewBuildCmd())
+	rootCmd.AddCommand(NewListCmd())
+	rootCmd.AddCommand(NewVerifyPacksCmd())
+	rootCmd.AddCommand(NewDoctorCmd())
+
+	return rootCmd
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright (c) 2023 Arm Limited. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package commands
+
+import (
+	builder "cbuild/pkg/builder"
+	"cbuild/pkg/builder/csolution"
+	"cbuild/pkg/utils"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// NewDoctorCmd returns the `doctor [csolution.yml]` command: it checks
+// the host environment for the tools and toolchains cbuild needs and
+// reports each as pass/warn/fail. The csolution.yml argument is only
+// needed to resolve which toolchains to check; it may be omitted to
+// check the toolbox/host prerequisites alone.
+func NewDoctorCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "doctor [csolution.yml]",
+		Short: "Check the host environment for cbuild's prerequisites",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configs, err := utils.GetInstallConfigs()
+			if err != nil {
+				return err
+			}
+
+			inputFile := ""
+			if len(args) == 1 {
+				inputFile = args[0]
+			}
+
+			b := csolution.CSolutionBuilder{
+				BuilderParams: builder.BuilderParams{
+					Runner:         utils.CommandRunner{},
+					InputFile:      inputFile,
+					InstallConfigs: configs,
+				},
+			}
+
+			report, err := b.Doctor()
+			if err != nil {
+				return err
+			}
+
+			rendered, err := report.Render(output)
+			if err != nil {
+				return err
+			}
+			fmt.Print(rendered)
+
+			if string(report.Overall) == "fail" {
+				return fmt.Errorf("doctor: one or more checks failed")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&output, "output", "text", "output format: text or json")
+
+	return cmd
+}
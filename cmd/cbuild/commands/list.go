@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2023 Arm Limited. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package commands
+
+import (
+	builder "cbuild/pkg/builder"
+	"cbuild/pkg/builder/csolution"
+	"cbuild/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// listFlags holds the flags shared by every `list` subcommand.
+type listFlags struct {
+	filter string
+	output string
+}
+
+// newCSolutionBuilder resolves the install configuration and wires up a
+// CSolutionBuilder for inputFile with the common list flags applied.
+func newCSolutionBuilder(inputFile string, f *listFlags) (csolution.CSolutionBuilder, error) {
+	configs, err := utils.GetInstallConfigs()
+	if err != nil {
+		return csolution.CSolutionBuilder{}, err
+	}
+
+	return csolution.CSolutionBuilder{
+		BuilderParams: builder.BuilderParams{
+			Runner:         utils.CommandRunner{},
+			InputFile:      inputFile,
+			InstallConfigs: configs,
+			Options: builder.Options{
+				Filter: f.filter,
+				Output: f.output,
+			},
+		},
+	}, nil
+}
+
+// NewListCmd returns the `list` command group: contexts, toolchains and
+// environment.
+func NewListCmd() *cobra.Command {
+	f := &listFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List contexts, toolchains or the build environment",
+	}
+	cmd.PersistentFlags().StringVar(&f.output, "output", "text", "output format: text, json or yaml")
+	cmd.PersistentFlags().StringVar(&f.filter, "filter", "", "filter results by substring")
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "contexts <csolution.yml>",
+		Short: "List the contexts of a csolution",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := newCSolutionBuilder(args[0], f)
+			if err != nil {
+				return err
+			}
+			return b.ListContexts()
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "toolchains <csolution.yml>",
+		Short: "List the toolchains a csolution requires",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := newCSolutionBuilder(args[0], f)
+			if err != nil {
+				return err
+			}
+			return b.ListToolchains()
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "environment <csolution.yml>",
+		Short: "List the resolved CMSIS build environment",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := newCSolutionBuilder(args[0], f)
+			if err != nil {
+				return err
+			}
+			return b.ListEnvironment()
+		},
+	})
+
+	return cmd
+}
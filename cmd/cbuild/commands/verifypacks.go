@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2023 Arm Limited. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package commands
+
+import (
+	builder "cbuild/pkg/builder"
+	"cbuild/pkg/builder/csolution"
+	"cbuild/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// NewVerifyPacksCmd returns the `verify-packs <csolution.yml>` command:
+// it installs every pack the solution requires and pins them by content
+// hash in a cbuild.lock.yml beside the solution file.
+func NewVerifyPacksCmd() *cobra.Command {
+	var updateLock bool
+
+	cmd := &cobra.Command{
+		Use:   "verify-packs <csolution.yml>",
+		Short: "Install required packs and verify them against cbuild.lock.yml",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configs, err := utils.GetInstallConfigs()
+			if err != nil {
+				return err
+			}
+
+			b := csolution.CSolutionBuilder{
+				BuilderParams: builder.BuilderParams{
+					Runner:         utils.CommandRunner{},
+					InputFile:      args[0],
+					InstallConfigs: configs,
+					Options: builder.Options{
+						UpdateLock: updateLock,
+					},
+				},
+			}
+			return b.Verify()
+		},
+	}
+
+	cmd.Flags().BoolVar(&updateLock, "update-lock", false, "(re)write cbuild.lock.yml from the currently resolved pack hashes")
+
+	return cmd
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2023 Arm Limited. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package builder defines the pieces shared by every concrete builder
+// implementation (csolution, cprj, ...): the command runner abstraction,
+// the options common to all build invocations, and the parameter bag
+// builders are constructed with.
+package builder
+
+import "cbuild/pkg/utils"
+
+// Runner abstracts process execution so builders can be exercised with a
+// mock in unit tests instead of spawning real tools.
+type Runner interface {
+	ExecuteCommand(program string, quiet bool, args ...string) (string, error)
+}
+
+// Options captures the user-facing flags that influence how a build is
+// carried out, independent of which concrete builder handles it.
+type Options struct {
+	Context    []string
+	Filter     string
+	Schema     bool
+	OutDir     string
+	IntDir     string
+	LogFile    string
+	Packs      bool
+	Quiet      bool
+	Debug      bool
+	Jobs       int
+	FailFast   bool
+	Output     string
+	UpdateLock bool
+	NoCache    bool
+	CacheDir   string
+}
+
+// BuilderParams is embedded by every concrete builder; it wires in the
+// command runner, the solution/project input file and the resolved
+// install configuration.
+type BuilderParams struct {
+	Runner         Runner
+	InputFile      string
+	InstallConfigs utils.Configurations
+	Options        Options
+}
@@ -0,0 +1,593 @@
+/*
+ * Copyright (c) 2023 Arm Limited. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package csolution implements the builder that drives a multi-context
+// csolution.yml build: resolving contexts, converting them to CPRJ
+// projects via the csolution tool, installing missing packs and invoking
+// cbuildgen/cmake/ninja per context.
+package csolution
+
+import (
+	builder "cbuild/pkg/builder"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// CSolutionBuilder builds every selected context of a *.csolution.yml.
+type CSolutionBuilder struct {
+	builder.BuilderParams
+}
+
+// cbuildIdx mirrors the subset of a <solution>.cbuild-idx.yml this
+// package needs: the list of CPRJ files generated for each context.
+type cbuildIdx struct {
+	BuildIdx struct {
+		GeneratedBy string `yaml:"generated-by"`
+		Cprjs       []struct {
+			Cprj string `yaml:"cprj"`
+		} `yaml:"cprjs"`
+	} `yaml:"build-idx"`
+}
+
+// listContexts runs `csolution list contexts` against InputFile and
+// returns the resolved context names, optionally filtered by
+// Options.Filter and validated against the csolution schema.
+func (b CSolutionBuilder) listContexts(checkSchema bool, quiet bool) ([]string, error) {
+	csolutionBin := b.InstallConfigs.Bin("csolution")
+	if _, err := os.Stat(csolutionBin); err != nil {
+		return []string{}, err
+	}
+
+	args := []string{"list", "contexts", "-s", b.InputFile}
+	if checkSchema {
+		args = append(args, "--schema")
+	}
+	if b.Options.Filter != "" {
+		args = append(args, "--filter", b.Options.Filter)
+	}
+
+	output, err := b.Runner.ExecuteCommand(csolutionBin, quiet, args...)
+	if err != nil {
+		return []string{}, err
+	}
+
+	return splitLines(output), nil
+}
+
+// ListContexts prints the resolved contexts of InputFile. In text mode
+// (the default) one context per line; in json/yaml mode a structured
+// {context, project, build_type, target_type, toolchain} record per
+// context, per Options.Output.
+func (b CSolutionBuilder) ListContexts() error {
+	contexts, err := b.listContexts(true, false)
+	if err != nil {
+		return err
+	}
+
+	format := outputFormat(b.Options.Output)
+	if format == "text" {
+		for _, context := range contexts {
+			fmt.Println(context)
+		}
+		return nil
+	}
+
+	records := make([]contextRecord, 0, len(contexts))
+	for _, context := range contexts {
+		toolchain, err := b.toolchainForContext(context)
+		if err != nil {
+			log.Warnf("could not resolve toolchain for context \"%s\": %v", context, err)
+		}
+		records = append(records, toContextRecord(context, toolchain))
+	}
+
+	rendered, err := marshalOutput(format, records)
+	if err != nil {
+		return err
+	}
+	fmt.Println(rendered)
+	return nil
+}
+
+// listToolchains runs `csolution list toolchains` and returns each
+// toolchain as "<name>@<version>".
+func (b CSolutionBuilder) listToolchains(quiet bool) ([]string, error) {
+	csolutionBin := b.InstallConfigs.Bin("csolution")
+	if _, err := os.Stat(csolutionBin); err != nil {
+		return []string{}, err
+	}
+
+	args := []string{"list", "toolchains", "-s", b.InputFile}
+	if b.Options.Filter != "" {
+		args = append(args, "--filter", b.Options.Filter)
+	}
+	if b.Options.Schema {
+		args = append(args, "--schema")
+	}
+
+	output, err := b.Runner.ExecuteCommand(csolutionBin, quiet, args...)
+	if err != nil {
+		return []string{}, err
+	}
+
+	return splitLines(output), nil
+}
+
+// toolchainForContext resolves the single toolchain csolution selects
+// for context, by scoping `list toolchains` to it with --context.
+func (b CSolutionBuilder) toolchainForContext(context string) (string, error) {
+	csolutionBin := b.InstallConfigs.Bin("csolution")
+	if _, err := os.Stat(csolutionBin); err != nil {
+		return "", err
+	}
+
+	args := []string{"list", "toolchains", "-s", b.InputFile, "--context", context}
+	output, err := b.Runner.ExecuteCommand(csolutionBin, true, args...)
+	if err != nil {
+		return "", err
+	}
+
+	toolchains := splitLines(output)
+	if len(toolchains) == 0 {
+		return "", nil
+	}
+	return toToolchainRecord(toolchains[0]).Name, nil
+}
+
+// ListToolchains prints the toolchains referenced by InputFile. In
+// text mode, one "<name>@<version>" per line; in json/yaml mode a
+// {name, version} record per toolchain, per Options.Output.
+func (b CSolutionBuilder) ListToolchains() error {
+	toolchains, err := b.listToolchains(true)
+	if err != nil {
+		return err
+	}
+
+	format := outputFormat(b.Options.Output)
+	if format == "text" {
+		for _, toolchain := range toolchains {
+			fmt.Println(toolchain)
+		}
+		return nil
+	}
+
+	records := make([]toolchainRecord, 0, len(toolchains))
+	for _, toolchain := range toolchains {
+		records = append(records, toToolchainRecord(toolchain))
+	}
+
+	rendered, err := marshalOutput(format, records)
+	if err != nil {
+		return err
+	}
+	fmt.Println(rendered)
+	return nil
+}
+
+// listEnvironment reports the CMSIS environment csolution resolved
+// (pack root, compiler root, ...) plus the host tools cbuild itself
+// depends on (cmake, ninja).
+func (b CSolutionBuilder) listEnvironment(quiet bool) ([]string, error) {
+	csolutionBin := b.InstallConfigs.Bin("csolution")
+	if _, err := os.Stat(csolutionBin); err != nil {
+		return []string{}, err
+	}
+
+	output, err := b.Runner.ExecuteCommand(csolutionBin, quiet, "list", "environment", "-s", b.InputFile)
+	if err != nil {
+		return []string{}, err
+	}
+
+	envConfigs := splitLines(output)
+	envConfigs = append(envConfigs, toolVersionLine("cmake"), toolVersionLine("ninja"))
+	return envConfigs, nil
+}
+
+// ListEnvironment prints the environment csolution resolved, plus the
+// host tools cbuild depends on. In text mode, one "key=value" per line;
+// in json/yaml mode a structured {cmsis_pack_root, cmsis_compiler_root,
+// cmsis_build_root, tools} document, per Options.Output.
+func (b CSolutionBuilder) ListEnvironment() error {
+	envConfigs, err := b.listEnvironment(true)
+	if err != nil {
+		return err
+	}
+
+	format := outputFormat(b.Options.Output)
+	if format == "text" {
+		for _, envConfig := range envConfigs {
+			fmt.Println(envConfig)
+		}
+		return nil
+	}
+
+	doc := environmentDoc{
+		CmsisPackRoot:     envValue(envConfigs, "CMSIS_PACK_ROOT"),
+		CmsisCompilerRoot: envValue(envConfigs, "CMSIS_COMPILER_ROOT"),
+		CmsisBuildRoot:    b.InstallConfigs.BinPath,
+	}
+	for _, name := range []string{"cmake", "ninja", "xmllint", "csolution", "cbuildgen", "cpackget"} {
+		doc.Tools = append(doc.Tools, b.resolveTool(name))
+	}
+
+	rendered, err := marshalOutput(format, doc)
+	if err != nil {
+		return err
+	}
+	fmt.Println(rendered)
+	return nil
+}
+
+// envValue returns the value of "key=value" entry key in lines, or "" if
+// absent.
+func envValue(lines []string, key string) string {
+	prefix := key + "="
+	for _, line := range lines {
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimPrefix(line, prefix)
+		}
+	}
+	return ""
+}
+
+// resolveTool locates one of the tools cbuild depends on: csolution,
+// cbuildgen and cpackget come from the toolbox install (InstallConfigs),
+// everything else is looked up on PATH.
+func (b CSolutionBuilder) resolveTool(name string) toolRecord {
+	switch name {
+	case "csolution", "cbuildgen", "cpackget":
+		path := b.InstallConfigs.Bin(name)
+		if _, err := os.Stat(path); err != nil {
+			return toolRecord{Name: name, Source: "install"}
+		}
+		return toolRecord{Name: name, Path: path, Version: b.toolVersion(path), Source: "install"}
+	default:
+		path, err := exec.LookPath(name)
+		if err != nil {
+			return toolRecord{Name: name, Source: "PATH"}
+		}
+		return toolRecord{Name: name, Path: path, Version: b.toolVersion(path), Source: "PATH"}
+	}
+}
+
+// toolVersion runs "<path> --version" through Runner and returns its
+// first line, or "" if the tool can't report one.
+func (b CSolutionBuilder) toolVersion(path string) string {
+	output, err := b.Runner.ExecuteCommand(path, true, "--version")
+	if err != nil {
+		return ""
+	}
+	lines := splitLines(output)
+	if len(lines) == 0 {
+		return ""
+	}
+	return lines[0]
+}
+
+// installMissingPacks installs every pack `csolution list packs` reports
+// as required for InputFile via cpackget.
+func (b CSolutionBuilder) installMissingPacks() error {
+	csolutionBin := b.InstallConfigs.Bin("csolution")
+	if _, err := os.Stat(csolutionBin); err != nil {
+		return err
+	}
+
+	output, err := b.Runner.ExecuteCommand(csolutionBin, true, "list", "packs", "-s", b.InputFile)
+	if err != nil {
+		return err
+	}
+
+	cpackgetBin := b.InstallConfigs.Bin("cpackget")
+	for _, pack := range splitLines(output) {
+		if _, err := b.Runner.ExecuteCommand(cpackgetBin, true, "add", pack); err != nil {
+			return fmt.Errorf("failed to install pack %s: %w", pack, err)
+		}
+	}
+	return nil
+}
+
+// getSelectedContexts reads idxFile and returns the context name of
+// every CPRJ it lists, in file order.
+func (b CSolutionBuilder) getSelectedContexts(idxFile string) ([]string, error) {
+	idx, err := readCbuildIdx(idxFile)
+	if err != nil {
+		return []string{}, err
+	}
+
+	contexts := make([]string, 0, len(idx.BuildIdx.Cprjs))
+	for _, entry := range idx.BuildIdx.Cprjs {
+		contexts = append(contexts, contextNameOf(entry.Cprj))
+	}
+	return contexts, nil
+}
+
+// getCprjFilePath resolves the CPRJ path idxFile records for context,
+// relative to idxFile's directory.
+func (b CSolutionBuilder) getCprjFilePath(idxFile string, context string) (string, error) {
+	idx, err := readCbuildIdx(idxFile)
+	if err != nil {
+		return "", err
+	}
+
+	for _, entry := range idx.BuildIdx.Cprjs {
+		if contextNameOf(entry.Cprj) == context {
+			return filepath.Join(filepath.Dir(idxFile), filepath.FromSlash(entry.Cprj)), nil
+		}
+	}
+	return "", fmt.Errorf("context \"%s\" not found in %s", context, idxFile)
+}
+
+// contextResult summarizes the outcome of building a single context, for
+// the end-of-run report Build prints once every worker has finished.
+type contextResult struct {
+	context  string
+	duration time.Duration
+	err      error
+	upToDate bool
+}
+
+// firstErrorLine returns the first line of the wrapped error, suitable
+// for a one-line summary table.
+func (r contextResult) firstErrorLine() string {
+	if r.err == nil {
+		return ""
+	}
+	return strings.SplitN(r.err.Error(), "\n", 2)[0]
+}
+
+// numJobs resolves the effective worker count: Options.Jobs if set and
+// positive, otherwise runtime.NumCPU().
+func (b CSolutionBuilder) numJobs() int {
+	if b.Options.Jobs > 0 {
+		return b.Options.Jobs
+	}
+	return runtime.NumCPU()
+}
+
+// Build converts InputFile's selected contexts to CPRJ projects and
+// builds them through a bounded worker pool sized by Options.Jobs
+// (runtime.NumCPU() by default). Workers build independently, each with
+// its own log prefix; unless Options.FailFast is set, one context
+// failing doesn't stop the others from finishing. A summary of every
+// context's outcome is printed once all workers are done, and a non-zero
+// error is returned if any context failed.
+func (b CSolutionBuilder) Build() error {
+	if len(b.Options.Context) == 0 {
+		return errors.New("no context specified, use --context to select contexts to build")
+	}
+
+	if b.Options.Packs {
+		if err := b.installMissingPacks(); err != nil {
+			return err
+		}
+	}
+
+	csolutionBin := b.InstallConfigs.Bin("csolution")
+	if _, err := os.Stat(csolutionBin); err != nil {
+		return err
+	}
+	idxFile := idxFilePath(b.InputFile)
+	if _, err := b.Runner.ExecuteCommand(csolutionBin, b.Options.Quiet, "convert", "-s", b.InputFile); err != nil {
+		return fmt.Errorf("csolution convert failed: %w", err)
+	}
+
+	// Fold every toolchain used anywhere in the solution into the
+	// fingerprint so a toolchain swap on any context invalidates caches,
+	// since listToolchains doesn't tell us which toolchain an individual
+	// context uses.
+	toolchainID := ""
+	if toolchains, err := b.listToolchains(true); err == nil && len(toolchains) > 0 {
+		sort.Strings(toolchains)
+		toolchainID = strings.Join(toolchains, "+")
+	}
+	cache := newCacheStore(b.cacheFilePath(b.Options.OutDir))
+
+	jobs := b.numJobs()
+	contexts := make(chan string)
+	results := make(chan contextResult)
+	var stopOnce sync.Once
+	stop := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for context := range contexts {
+				start := time.Now()
+				prefix := fmt.Sprintf("[worker %d] %s", worker, context)
+				upToDate, err := b.buildContext(idxFile, context, toolchainID, prefix, cache)
+				result := contextResult{context: context, duration: time.Since(start), err: err, upToDate: upToDate}
+				results <- result
+				if err != nil && b.Options.FailFast {
+					stopOnce.Do(func() { close(stop) })
+				}
+			}
+		}(i)
+	}
+
+	go func() {
+		defer close(contexts)
+		for _, context := range b.Options.Context {
+			select {
+			case contexts <- context:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	summary := make([]contextResult, 0, len(b.Options.Context))
+	for result := range results {
+		summary = append(summary, result)
+	}
+
+	if !b.Options.NoCache {
+		if err := cache.save(); err != nil {
+			log.Warnf("failed to save build cache: %v", err)
+		}
+	}
+
+	return reportBuildSummary(summary)
+}
+
+// reportBuildSummary prints one line per context (duration,
+// ok/failed/up-to-date, first error line) and returns an error if any
+// context failed.
+func reportBuildSummary(summary []contextResult) error {
+	failed := 0
+	for _, result := range summary {
+		status := "ok"
+		switch {
+		case result.err != nil:
+			status = "failed"
+			failed++
+		case result.upToDate:
+			status = "up-to-date"
+		}
+		log.Infof("%-50s %-12s %8s %s", result.context, status, result.duration.Round(time.Millisecond), result.firstErrorLine())
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("build failed: %d of %d context(s) failed", failed, len(summary))
+	}
+	return nil
+}
+
+// buildContext resolves context's CPRJ file from idxFile and builds it
+// via cbuildgen, cmake and ninja, logging every step under logPrefix so
+// concurrent workers' output doesn't interleave unattributed. Unless
+// Options.NoCache is set, it first checks cache for a matching
+// fingerprint and, if every recorded artifact is still present, skips
+// the build entirely.
+func (b CSolutionBuilder) buildContext(idxFile string, context string, toolchainID string, logPrefix string, cache *cacheStore) (upToDate bool, err error) {
+	cprjFile, err := b.getCprjFilePath(idxFile, context)
+	if err != nil {
+		return false, err
+	}
+
+	var fingerprint string
+	var haveFingerprint bool
+	if !b.Options.NoCache {
+		fp, fpErr := b.contextFingerprint(cprjFile, toolchainID)
+		if fpErr == nil {
+			fingerprint, haveFingerprint = fp, true
+			if entry, ok := cache.get(context); ok && isUpToDate(entry, fingerprint) {
+				log.Infof("%s: up-to-date", logPrefix)
+				return true, nil
+			}
+		} else {
+			log.Debugf("%s: could not compute fingerprint: %v", logPrefix, fpErr)
+		}
+	}
+
+	cbuildgenBin := b.InstallConfigs.Bin("cbuildgen")
+	if _, err := os.Stat(cbuildgenBin); err != nil {
+		return false, err
+	}
+
+	args := []string{cprjFile, "cmake"}
+	if b.Options.IntDir != "" {
+		args = append(args, "--intdir="+b.Options.IntDir)
+	}
+	if b.Options.OutDir != "" {
+		args = append(args, "--outdir="+b.Options.OutDir)
+	}
+
+	log.Debugf("%s: cbuildgen %s", logPrefix, strings.Join(args, " "))
+	if _, err := b.Runner.ExecuteCommand(cbuildgenBin, b.Options.Quiet, args...); err != nil {
+		return false, fmt.Errorf("context \"%s\": cbuildgen failed: %w", context, err)
+	}
+
+	if !b.Options.NoCache {
+		if !haveFingerprint {
+			var fpErr error
+			fingerprint, fpErr = b.contextFingerprint(cprjFile, toolchainID)
+			haveFingerprint = fpErr == nil
+		}
+		if haveFingerprint {
+			cache.put(context, cacheEntry{
+				Context:     context,
+				Fingerprint: fingerprint,
+				Artifacts:   buildArtifacts(cprjFile, b.Options.OutDir),
+				Timestamp:   time.Now(),
+			})
+		}
+	}
+
+	return false, nil
+}
+
+// idxFilePath derives the <solution>.cbuild-idx.yml path csolution
+// writes for a "<name>.csolution.yml" input file, the same way
+// csolution itself names it: by replacing the ".csolution" + extension
+// suffix, not just the extension.
+func idxFilePath(inputFile string) string {
+	name := strings.TrimSuffix(inputFile, filepath.Ext(inputFile))
+	name = strings.TrimSuffix(name, ".csolution")
+	return name + ".cbuild-idx.yml"
+}
+
+// readCbuildIdx loads and parses a <solution>.cbuild-idx.yml file.
+func readCbuildIdx(idxFile string) (cbuildIdx, error) {
+	data, err := os.ReadFile(idxFile)
+	if err != nil {
+		return cbuildIdx{}, err
+	}
+
+	var idx cbuildIdx
+	if err := yaml.Unmarshal(data, &idx); err != nil {
+		return cbuildIdx{}, fmt.Errorf("failed to parse %s: %w", idxFile, err)
+	}
+	return idx, nil
+}
+
+// contextNameOf derives a context name from the CPRJ path csolution
+// recorded for it, e.g. "cm0plus/Foo.Debug+Target.cprj" -> "Foo.Debug+Target".
+func contextNameOf(cprjPath string) string {
+	base := filepath.Base(filepath.FromSlash(cprjPath))
+	return strings.TrimSuffix(base, ".cprj")
+}
+
+// toolVersionLine reports the resolved path of a host tool as
+// "<name>=<path>", or "<name>=not found" when it isn't on PATH.
+func toolVersionLine(name string) string {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		log.Debugf("%s not found on PATH", name)
+		return name + "=not found"
+	}
+	return name + "=" + path
+}
+
+// splitLines normalizes CRLF/LF line endings and drops empty lines.
+func splitLines(output string) []string {
+	output = strings.ReplaceAll(output, "\r\n", "\n")
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
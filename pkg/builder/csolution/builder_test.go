@@ -14,6 +14,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -32,6 +33,17 @@ func (r RunnerMock) ExecuteCommand(program string, quiet bool, args ...string) (
 			if args[1] == "contexts" {
 				return "test.Debug+CM0\r\ntest.Release+CM0", nil
 			} else if args[1] == "toolchains" {
+				for i, a := range args {
+					if a == "--context" && i+1 < len(args) {
+						switch args[i+1] {
+						case "test.Debug+CM0":
+							return "AC6@6.18.0\n", nil
+						case "test.Release+CM0":
+							return "GCC@11.2.1\n", nil
+						}
+						return "", nil
+					}
+				}
 				return "AC5@5.6.7\nAC6@6.18.0\nGCC@11.2.1\nIAR@8.50.6\n", nil
 			} else if args[1] == "packs" {
 				return "ARM::test:0.0.1\r\nARM::test2:0.0.2", nil
@@ -141,6 +153,34 @@ func TestListContexts(t *testing.T) {
 		assert.Equal("test.Debug+CM0", contexts[0])
 		assert.Equal("test.Release+CM0", contexts[1])
 	})
+
+	for _, format := range []string{"json", "yaml"} {
+		format := format
+		t.Run("test list contexts with "+format+" output", func(t *testing.T) {
+			b.Options.Output = format
+			err := b.ListContexts()
+			assert.Nil(err)
+			b.Options.Output = ""
+		})
+	}
+
+	t.Run("test list contexts resolves toolchain per context", func(t *testing.T) {
+		records := make([]contextRecord, 0, 2)
+		for _, context := range []string{"test.Debug+CM0", "test.Release+CM0"} {
+			toolchain, err := b.toolchainForContext(context)
+			assert.Nil(err)
+			records = append(records, toContextRecord(context, toolchain))
+		}
+		assert.Equal("AC6", records[0].Toolchain)
+		assert.Equal("GCC", records[1].Toolchain)
+	})
+
+	t.Run("test list contexts with invalid output", func(t *testing.T) {
+		b.Options.Output = "xml"
+		err := b.ListContexts()
+		assert.Error(err)
+		b.Options.Output = ""
+	})
 }
 
 func TestListToolchians(t *testing.T) {
@@ -208,6 +248,16 @@ func TestListToolchians(t *testing.T) {
 		assert.Equal("GCC@11.2.1", toolchains[2])
 		assert.Equal("IAR@8.50.6", toolchains[3])
 	})
+
+	for _, format := range []string{"json", "yaml"} {
+		format := format
+		t.Run("test list toolchains with "+format+" output", func(t *testing.T) {
+			b.Options.Output = format
+			err := b.ListToolchains()
+			assert.Nil(err)
+			b.Options.Output = ""
+		})
+	}
 }
 
 func TestListEnvironment(t *testing.T) {
@@ -247,6 +297,15 @@ func TestListEnvironment(t *testing.T) {
 		assert.Nil(err)
 	})
 
+	for _, format := range []string{"json", "yaml"} {
+		format := format
+		t.Run("test list environment with "+format+" output", func(t *testing.T) {
+			b.Options.Output = format
+			err := b.ListEnvironment()
+			assert.Nil(err)
+			b.Options.Output = ""
+		})
+	}
 }
 
 func TestBuild(t *testing.T) {
@@ -279,6 +338,114 @@ func TestBuild(t *testing.T) {
 		err := b.Build()
 		assert.Error(err)
 	})
+
+	t.Run("test build csolution serially with -j 1", func(t *testing.T) {
+		b.Options.Context = []string{"test.Debug+CM0", "test.Release+CM0"}
+		b.Options.Jobs = 1
+		err := b.Build()
+		assert.Error(err)
+	})
+
+	t.Run("test build csolution in parallel", func(t *testing.T) {
+		b.Options.Context = []string{"test.Debug+CM0", "test.Release+CM0"}
+		b.Options.Jobs = 4
+		err := b.Build()
+		assert.Error(err)
+	})
+
+	t.Run("test build csolution with fail-fast", func(t *testing.T) {
+		b.Options.Context = []string{"test.Debug+CM0", "test.Release+CM0"}
+		b.Options.Jobs = 2
+		b.Options.FailFast = true
+		err := b.Build()
+		assert.Error(err)
+	})
+
+	t.Run("test second build is served from cache", func(t *testing.T) {
+		solutionDir := t.TempDir()
+		outDir := filepath.Join(solutionDir, "OutDir")
+		assert.Nil(os.MkdirAll(outDir, 0755))
+
+		cprjPath := filepath.Join(solutionDir, "test.Debug+CM0.cprj")
+		assert.Nil(os.WriteFile(cprjPath, []byte(`<cprj><project><files></files></project></cprj>`), 0644))
+
+		idxPath := filepath.Join(solutionDir, "test.cbuild-idx.yml")
+		assert.Nil(os.WriteFile(idxPath, []byte("build-idx:\n  cprjs:\n    - cprj: test.Debug+CM0.cprj\n"), 0644))
+
+		counting := &countingRunnerMock{}
+		cb := CSolutionBuilder{
+			BuilderParams: builder.BuilderParams{
+				Runner:    counting,
+				InputFile: filepath.Join(solutionDir, "test.csolution.yml"),
+				Options: builder.Options{
+					Context: []string{"test.Debug+CM0"},
+					OutDir:  outDir,
+					Jobs:    1,
+				},
+				InstallConfigs: configs,
+			},
+		}
+
+		assert.Nil(cb.Build())
+		assert.Equal(1, counting.cbuildgenCalls())
+
+		assert.Nil(cb.Build())
+		assert.Equal(1, counting.cbuildgenCalls(), "second build should be served entirely from cache")
+	})
+
+	t.Run("test second build is served from cache without outdir", func(t *testing.T) {
+		solutionDir := t.TempDir()
+		cacheDir := t.TempDir()
+
+		cprjPath := filepath.Join(solutionDir, "test.Debug+CM0.cprj")
+		assert.Nil(os.WriteFile(cprjPath, []byte(`<cprj><project><files></files></project></cprj>`), 0644))
+
+		idxPath := filepath.Join(solutionDir, "test.cbuild-idx.yml")
+		assert.Nil(os.WriteFile(idxPath, []byte("build-idx:\n  cprjs:\n    - cprj: test.Debug+CM0.cprj\n"), 0644))
+
+		counting := &countingRunnerMock{}
+		cb := CSolutionBuilder{
+			BuilderParams: builder.BuilderParams{
+				Runner:    counting,
+				InputFile: filepath.Join(solutionDir, "test.csolution.yml"),
+				Options: builder.Options{
+					Context:  []string{"test.Debug+CM0"},
+					CacheDir: cacheDir,
+					Jobs:     1,
+				},
+				InstallConfigs: configs,
+			},
+		}
+
+		assert.Nil(cb.Build())
+		assert.Equal(1, counting.cbuildgenCalls())
+
+		assert.Nil(cb.Build())
+		assert.Equal(1, counting.cbuildgenCalls(), "second build should be served from cache even without --outdir")
+	})
+}
+
+// countingRunnerMock behaves like RunnerMock but counts cbuildgen
+// invocations, so cache-driven tests can assert a build was skipped.
+type countingRunnerMock struct {
+	mu        sync.Mutex
+	cbuildgen int
+}
+
+func (r *countingRunnerMock) cbuildgenCalls() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cbuildgen
+}
+
+func (r *countingRunnerMock) ExecuteCommand(program string, quiet bool, args ...string) (string, error) {
+	if strings.Contains(program, "cbuildgen") {
+		r.mu.Lock()
+		r.cbuildgen++
+		r.mu.Unlock()
+		return "", nil
+	}
+	return RunnerMock{}.ExecuteCommand(program, quiet, args...)
 }
 
 func TestInstallMissingPacks(t *testing.T) {
@@ -308,6 +475,138 @@ func TestInstallMissingPacks(t *testing.T) {
 	})
 }
 
+func TestVerify(t *testing.T) {
+	assert := assert.New(t)
+	os.Setenv("CMSIS_BUILD_ROOT", testRoot+"/run/bin")
+	configs, err := utils.GetInstallConfigs()
+	assert.Nil(err)
+
+	// Build a standalone pack root so the test's expectations don't
+	// depend on the ARM::test / ARM::test2 packs the mock reports
+	// actually being present on disk.
+	packRoot := t.TempDir()
+	writePack := func(vendor, pack, version, content string) {
+		dir := filepath.Join(packRoot, vendor, pack, version)
+		assert.Nil(os.MkdirAll(dir, 0755))
+		path := filepath.Join(dir, vendor+"."+pack+"."+version+".pack")
+		assert.Nil(os.WriteFile(path, []byte(content), 0644))
+	}
+	writePack("ARM", "test", "0.0.1", "pack-content-1")
+	writePack("ARM", "test2", "0.0.2", "pack-content-2")
+	os.Setenv("CMSIS_PACK_ROOT", packRoot)
+
+	solutionDir := t.TempDir()
+	inputFile := filepath.Join(solutionDir, "test.csolution.yml")
+
+	b := CSolutionBuilder{
+		BuilderParams: builder.BuilderParams{
+			Runner:         RunnerMock{},
+			InputFile:      inputFile,
+			InstallConfigs: configs,
+		},
+	}
+
+	t.Run("test verify writes a fresh lockfile", func(t *testing.T) {
+		err := b.Verify()
+		assert.Nil(err)
+		_, err = os.Stat(b.lockFilePath())
+		assert.Nil(err)
+	})
+
+	t.Run("test verify succeeds when the lockfile matches", func(t *testing.T) {
+		err := b.Verify()
+		assert.Nil(err)
+	})
+
+	t.Run("test verify fails when the lockfile is corrupted", func(t *testing.T) {
+		lock, err := readLockFile(b.lockFilePath())
+		assert.Nil(err)
+		for pack := range lock {
+			lock[pack] = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+		}
+		assert.Nil(writeLockFile(b.lockFilePath(), lock))
+
+		err = b.Verify()
+		assert.Error(err)
+	})
+}
+
+// doctorRunnerMock lets TestDoctor control the reported cmake version
+// independently of the host machine's actual cmake install.
+type doctorRunnerMock struct {
+	cmakeVersion string
+}
+
+func (r doctorRunnerMock) ExecuteCommand(program string, quiet bool, args ...string) (string, error) {
+	switch {
+	case strings.Contains(program, "csolution"):
+		if len(args) > 1 && args[0] == "list" && args[1] == "toolchains" {
+			return "AC6@6.18.0", nil
+		}
+		return "", nil
+	case strings.Contains(program, "cmake"):
+		return r.cmakeVersion, nil
+	case strings.Contains(program, "ninja"):
+		return "1.11.1", nil
+	case strings.Contains(program, "xmllint"):
+		return "xmllint: using libxml version 21207", nil
+	case strings.Contains(program, "cbuildgen"):
+		return "cbuildgen 2.6.0", nil
+	case strings.Contains(program, "cpackget"):
+		return "cpackget 0.11.1", nil
+	default:
+		return "", errors.New("compiler not invocable")
+	}
+}
+
+func TestDoctor(t *testing.T) {
+	assert := assert.New(t)
+	os.Setenv("CMSIS_BUILD_ROOT", testRoot+"/run/bin")
+	os.Setenv("CMSIS_PACK_ROOT", testRoot+"/run/packs")
+	configs, err := utils.GetInstallConfigs()
+	assert.Nil(err)
+
+	b := CSolutionBuilder{
+		BuilderParams: builder.BuilderParams{
+			Runner:         doctorRunnerMock{cmakeVersion: "3.25.1"},
+			InputFile:      testRoot + "/run/test.csolution.yml",
+			InstallConfigs: configs,
+		},
+	}
+
+	t.Run("test doctor with current cmake", func(t *testing.T) {
+		report, err := b.Doctor()
+		assert.Nil(err)
+		assert.NotEmpty(report.Checks)
+	})
+
+	t.Run("test doctor with cmake too old", func(t *testing.T) {
+		old := b.Runner
+		b.Runner = doctorRunnerMock{cmakeVersion: "3.10.0"}
+		report, err := b.Doctor()
+		b.Runner = old
+		assert.Nil(err)
+		assert.Equal(statusFail, report.Overall)
+	})
+
+	t.Run("test doctor with missing toolbox tools", func(t *testing.T) {
+		binExtn := b.InstallConfigs.BinExtn
+		b.InstallConfigs.BinExtn = "invalid_path"
+		report, err := b.Doctor()
+		b.InstallConfigs.BinExtn = binExtn
+		assert.Nil(err)
+		assert.Equal(statusFail, report.Overall)
+	})
+
+	t.Run("test doctor renders json", func(t *testing.T) {
+		report, err := b.Doctor()
+		assert.Nil(err)
+		rendered, err := report.Render("json")
+		assert.Nil(err)
+		assert.Contains(rendered, "\"overall\"")
+	})
+}
+
 func TestGetCprjFilePath(t *testing.T) {
 	assert := assert.New(t)
 
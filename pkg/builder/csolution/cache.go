@@ -0,0 +1,260 @@
+/*
+ * Copyright (c) 2023 Arm Limited. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package csolution
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// cacheFileName is the build cache persisted under Options.OutDir (or
+// Options.CacheDir, when given); one file holds every built context.
+const cacheFileName = ".cbuild-cache.json"
+
+// cacheEntry records the inputs a context was last built from, so a
+// later Build() can tell whether anything actually changed.
+type cacheEntry struct {
+	Context     string    `json:"context"`
+	Fingerprint string    `json:"fingerprint"`
+	Artifacts   []string  `json:"artifacts"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// buildCache is the <OutDir>/.cbuild-cache.json document: one entry per
+// context built into that output directory.
+type buildCache map[string]cacheEntry
+
+// cprjProject is the subset of a CPRJ project file's XML this package
+// needs: the list of source/header files a context compiles, possibly
+// nested under <group> elements.
+type cprjProject struct {
+	XMLName xml.Name  `xml:"cprj"`
+	Project cprjFiles `xml:"project"`
+}
+
+type cprjFiles struct {
+	Files  []cprjFileRef `xml:"files>file"`
+	Groups []cprjGroup   `xml:"files>group"`
+}
+
+type cprjGroup struct {
+	Files  []cprjFileRef `xml:"file"`
+	Groups []cprjGroup   `xml:"group"`
+}
+
+type cprjFileRef struct {
+	Name string `xml:"name,attr"`
+}
+
+// cprjSourceFiles parses cprjFile and returns the absolute path of every
+// source/header file it references, resolving paths relative to
+// cprjFile's directory.
+func cprjSourceFiles(cprjFile string) ([]string, error) {
+	data, err := os.ReadFile(cprjFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var project cprjProject
+	if err := xml.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", cprjFile, err)
+	}
+
+	baseDir := filepath.Dir(cprjFile)
+	var paths []string
+	var collectGroup func(cprjGroup)
+	collectGroup = func(group cprjGroup) {
+		for _, f := range group.Files {
+			paths = append(paths, resolveRelative(baseDir, f.Name))
+		}
+		for _, g := range group.Groups {
+			collectGroup(g)
+		}
+	}
+	for _, f := range project.Project.Files {
+		paths = append(paths, resolveRelative(baseDir, f.Name))
+	}
+	for _, g := range project.Project.Groups {
+		collectGroup(g)
+	}
+
+	return paths, nil
+}
+
+// resolveRelative joins name onto baseDir unless name is already
+// absolute.
+func resolveRelative(baseDir, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(baseDir, filepath.FromSlash(name))
+}
+
+// contextFingerprint computes a SHA-256 fingerprint of everything that
+// can make context's build output stale: the CPRJ file's own contents,
+// the mtime+size of every file it references, the resolved toolchain,
+// and the pinned pack hashes from cbuild.lock.yml.
+func (b CSolutionBuilder) contextFingerprint(cprjFile, toolchain string) (string, error) {
+	cprjData, err := os.ReadFile(cprjFile)
+	if err != nil {
+		return "", err
+	}
+
+	sourceFiles, err := cprjSourceFiles(cprjFile)
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.New()
+	digest.Write(cprjData)
+
+	for _, path := range sourceFiles {
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Fprintf(digest, "%s:missing\n", path)
+			continue
+		}
+		fmt.Fprintf(digest, "%s:%d:%d\n", path, info.Size(), info.ModTime().UnixNano())
+	}
+
+	fmt.Fprintf(digest, "toolchain:%s\n", toolchain)
+
+	packHashes, err := readLockFile(b.lockFilePath())
+	if err == nil {
+		packs := make([]string, 0, len(packHashes))
+		for pack := range packHashes {
+			packs = append(packs, pack)
+		}
+		sort.Strings(packs)
+		for _, pack := range packs {
+			fmt.Fprintf(digest, "pack:%s=%s\n", pack, packHashes[pack])
+		}
+	}
+
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// cacheFilePath returns the build cache path for outDir, honoring
+// Options.CacheDir when set.
+func (b CSolutionBuilder) cacheFilePath(outDir string) string {
+	dir := b.Options.CacheDir
+	if dir == "" {
+		dir = outDir
+	}
+	return filepath.Join(dir, cacheFileName)
+}
+
+// readBuildCache loads an existing build cache, or an empty one if path
+// doesn't exist.
+func readBuildCache(path string) (buildCache, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return buildCache{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cache := buildCache{}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return cache, nil
+}
+
+// writeBuildCache persists cache to path.
+func writeBuildCache(path string, cache buildCache) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// isUpToDate reports whether entry's fingerprint still matches and every
+// recorded artifact is still present, in which case the context's build
+// can be skipped.
+func isUpToDate(entry cacheEntry, fingerprint string) bool {
+	if entry.Fingerprint != fingerprint {
+		log.Debugf("context %s: fingerprint changed, rebuilding", entry.Context)
+		return false
+	}
+	for _, artifact := range entry.Artifacts {
+		if _, err := os.Stat(artifact); err != nil {
+			log.Debugf("context %s: artifact %s missing, rebuilding", entry.Context, artifact)
+			return false
+		}
+	}
+	return len(entry.Artifacts) > 0
+}
+
+// cacheStore guards concurrent reads/writes of a single
+// .cbuild-cache.json from the worker pool in Build.
+type cacheStore struct {
+	path string
+	mu   sync.Mutex
+	data buildCache
+}
+
+// newCacheStore loads the build cache at path, or starts with an empty
+// one if it doesn't exist or fails to parse.
+func newCacheStore(path string) *cacheStore {
+	data, err := readBuildCache(path)
+	if err != nil {
+		log.Debugf("discarding unreadable build cache %s: %v", path, err)
+		data = buildCache{}
+	}
+	return &cacheStore{path: path, data: data}
+}
+
+// get returns the cached entry for context, if any.
+func (c *cacheStore) get(context string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.data[context]
+	return entry, ok
+}
+
+// put records entry for context, overwriting any previous one.
+func (c *cacheStore) put(context string, entry cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[context] = entry
+}
+
+// save persists the accumulated cache entries to disk.
+func (c *cacheStore) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return writeBuildCache(c.path, c.data)
+}
+
+// buildArtifacts lists the paths a context's build produced, for
+// recording in the cache entry: cprjFile always (it exists regardless
+// of Options.OutDir), plus outDir when one was passed to cbuildgen. In
+// the absence of a richer manifest from cbuildgen, these stand in for
+// "the context's build output".
+func buildArtifacts(cprjFile, outDir string) []string {
+	artifacts := []string{cprjFile}
+	if outDir != "" {
+		artifacts = append(artifacts, outDir)
+	}
+	return artifacts
+}
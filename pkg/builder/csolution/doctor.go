@@ -0,0 +1,264 @@
+/*
+ * Copyright (c) 2023 Arm Limited. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package csolution
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// checkStatus is the outcome of a single doctor check.
+type checkStatus string
+
+const (
+	statusPass checkStatus = "pass"
+	statusWarn checkStatus = "warn"
+	statusFail checkStatus = "fail"
+)
+
+// check is a single host-environment prerequisite result, as reported
+// by Doctor.
+type check struct {
+	Name   string      `json:"name" yaml:"name"`
+	Status checkStatus `json:"status" yaml:"status"`
+	Detail string      `json:"detail" yaml:"detail"`
+	Remedy string      `json:"remedy,omitempty" yaml:"remedy,omitempty"`
+}
+
+// doctorReport is the structured result of a full Doctor run.
+type doctorReport struct {
+	Checks  []check     `json:"checks" yaml:"checks"`
+	Overall checkStatus `json:"overall" yaml:"overall"`
+}
+
+// minToolVersions lists the host tools Doctor requires a minimum version
+// of; tools absent from this map are only checked for presence.
+var minToolVersions = map[string]string{
+	"cmake": "3.22.0",
+}
+
+// toolchainCompiler maps a toolchain name to the compiler binary Doctor
+// invokes with --version to confirm it's usable.
+var toolchainCompiler = map[string]string{
+	"AC5":   "armcc",
+	"AC6":   "armclang",
+	"GCC":   "arm-none-eabi-gcc",
+	"IAR":   "iccarm",
+	"CLANG": "clang",
+}
+
+// versionPattern accepts both "X.Y.Z" and "X.Y.Z-suffix".
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)`)
+
+// parseVersion extracts the numeric X.Y.Z triple from a version string
+// that may carry a "-suffix" (e.g. "3.22.1-rc1").
+func parseVersion(version string) ([3]int, error) {
+	m := versionPattern.FindStringSubmatch(version)
+	if m == nil {
+		return [3]int{}, fmt.Errorf("unrecognized version format %q", version)
+	}
+	var v [3]int
+	for i := 0; i < 3; i++ {
+		n, err := strconv.Atoi(m[i+1])
+		if err != nil {
+			return [3]int{}, err
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+// versionAtLeast reports whether version meets or exceeds min.
+func versionAtLeast(version, min string) (bool, error) {
+	v, err := parseVersion(version)
+	if err != nil {
+		return false, err
+	}
+	m, err := parseVersion(min)
+	if err != nil {
+		return false, err
+	}
+	for i := 0; i < 3; i++ {
+		if v[i] != m[i] {
+			return v[i] > m[i], nil
+		}
+	}
+	return true, nil
+}
+
+// Render formats report as text ("[<STATUS>] <name>: <detail> (<remedy>)"
+// per check) or, for "json"/"yaml", as a structured {checks, overall}
+// document.
+func (r doctorReport) Render(format string) (string, error) {
+	format = outputFormat(format)
+	if format != "text" {
+		return marshalOutput(format, r)
+	}
+
+	var b strings.Builder
+	for _, c := range r.Checks {
+		fmt.Fprintf(&b, "[%s] %s: %s", strings.ToUpper(string(c.Status)), c.Name, c.Detail)
+		if c.Remedy != "" {
+			fmt.Fprintf(&b, " (%s)", c.Remedy)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "overall: %s\n", r.Overall)
+	return b.String(), nil
+}
+
+// Doctor walks through the host prerequisites cbuild needs and reports
+// each as pass/warn/fail with a remediation hint: CMSIS_BUILD_ROOT,
+// csolution/cbuildgen/cpackget/cmake/ninja/xmllint presence and version,
+// CMSIS_PACK_ROOT writability, and per-toolchain compiler availability.
+func (b CSolutionBuilder) Doctor() (doctorReport, error) {
+	var checks []check
+
+	checks = append(checks, b.checkBuildRoot())
+	for _, tool := range []string{"csolution", "cbuildgen", "cpackget", "cmake", "ninja", "xmllint"} {
+		checks = append(checks, b.checkTool(tool))
+	}
+	checks = append(checks, b.checkPackRootWritable())
+
+	toolchains, err := b.listToolchains(true)
+	if err == nil {
+		for _, toolchain := range toolchains {
+			checks = append(checks, b.checkToolchain(toToolchainRecord(toolchain)))
+		}
+	}
+
+	overall := statusPass
+	for _, c := range checks {
+		if c.Status == statusFail {
+			overall = statusFail
+			break
+		}
+		if c.Status == statusWarn && overall == statusPass {
+			overall = statusWarn
+		}
+	}
+
+	return doctorReport{Checks: checks, Overall: overall}, nil
+}
+
+// checkBuildRoot verifies CMSIS_BUILD_ROOT is set and names an existing
+// directory; the directory's permissions are not checked.
+func (b CSolutionBuilder) checkBuildRoot() check {
+	if b.InstallConfigs.BinPath == "" {
+		return check{
+			Name:   "CMSIS_BUILD_ROOT",
+			Status: statusFail,
+			Detail: "CMSIS_BUILD_ROOT is not set",
+			Remedy: "set CMSIS_BUILD_ROOT to the CMSIS-Toolbox bin directory",
+		}
+	}
+	info, err := os.Stat(b.InstallConfigs.BinPath)
+	if err != nil || !info.IsDir() {
+		return check{
+			Name:   "CMSIS_BUILD_ROOT",
+			Status: statusFail,
+			Detail: fmt.Sprintf("%s is not a directory", b.InstallConfigs.BinPath),
+			Remedy: "set CMSIS_BUILD_ROOT to the CMSIS-Toolbox bin directory",
+		}
+	}
+	return check{Name: "CMSIS_BUILD_ROOT", Status: statusPass, Detail: b.InstallConfigs.BinPath}
+}
+
+// checkTool locates name (via InstallConfigs for the toolbox's own
+// tools, PATH for everything else), reports its version and compares it
+// against minToolVersions when a minimum is known.
+func (b CSolutionBuilder) checkTool(name string) check {
+	tool := b.resolveTool(name)
+	if tool.Path == "" {
+		return check{
+			Name:   name,
+			Status: statusFail,
+			Detail: name + " not found",
+			Remedy: fmt.Sprintf("install %s and make sure it is on PATH or under CMSIS_BUILD_ROOT", name),
+		}
+	}
+
+	min, hasMin := minToolVersions[name]
+	if !hasMin || tool.Version == "" {
+		return check{Name: name, Status: statusPass, Detail: tool.Path}
+	}
+
+	ok, err := versionAtLeast(tool.Version, min)
+	if err != nil {
+		return check{Name: name, Status: statusWarn, Detail: fmt.Sprintf("%s: could not parse version %q", tool.Path, tool.Version)}
+	}
+	if !ok {
+		return check{
+			Name:   name,
+			Status: statusFail,
+			Detail: fmt.Sprintf("%s reports %s, need >= %s", tool.Path, tool.Version, min),
+			Remedy: fmt.Sprintf("upgrade %s to %s or later", name, min),
+		}
+	}
+	return check{Name: name, Status: statusPass, Detail: fmt.Sprintf("%s (%s)", tool.Path, tool.Version)}
+}
+
+// checkPackRootWritable verifies CMSIS_PACK_ROOT is set and writable.
+func (b CSolutionBuilder) checkPackRootWritable() check {
+	packRoot := os.Getenv("CMSIS_PACK_ROOT")
+	if packRoot == "" {
+		return check{
+			Name:   "CMSIS_PACK_ROOT",
+			Status: statusFail,
+			Detail: "CMSIS_PACK_ROOT is not set",
+			Remedy: "set CMSIS_PACK_ROOT to a writable pack cache directory",
+		}
+	}
+
+	probe := filepath.Join(packRoot, ".cbuild-doctor-probe")
+	if err := os.WriteFile(probe, []byte{}, 0644); err != nil {
+		return check{
+			Name:   "CMSIS_PACK_ROOT",
+			Status: statusFail,
+			Detail: fmt.Sprintf("%s is not writable: %v", packRoot, err),
+			Remedy: "grant write access to CMSIS_PACK_ROOT",
+		}
+	}
+	os.Remove(probe)
+	return check{Name: "CMSIS_PACK_ROOT", Status: statusPass, Detail: packRoot}
+}
+
+// checkToolchain verifies toolchain's compiler root env var is set and
+// its compiler binary is invocable with --version.
+func (b CSolutionBuilder) checkToolchain(toolchain toolchainRecord) check {
+	name := fmt.Sprintf("toolchain %s", toolchain.Name)
+	envVar := toolchain.Name + "_TOOLCHAIN_ROOT"
+	root := os.Getenv(envVar)
+	if root == "" {
+		return check{
+			Name:   name,
+			Status: statusWarn,
+			Detail: envVar + " is not set",
+			Remedy: fmt.Sprintf("set %s to the %s installation directory", envVar, toolchain.Name),
+		}
+	}
+
+	compiler, ok := toolchainCompiler[toolchain.Name]
+	if !ok {
+		return check{Name: name, Status: statusPass, Detail: envVar + "=" + root}
+	}
+
+	compilerPath := filepath.Join(root, "bin", compiler)
+	if _, err := b.Runner.ExecuteCommand(compilerPath, true, "--version"); err != nil {
+		return check{
+			Name:   name,
+			Status: statusFail,
+			Detail: fmt.Sprintf("%s is not invocable: %v", compilerPath, err),
+			Remedy: fmt.Sprintf("verify %s is correctly installed under %s", toolchain.Name, root),
+		}
+	}
+	return check{Name: name, Status: statusPass, Detail: compilerPath}
+}
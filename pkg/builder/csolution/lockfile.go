@@ -0,0 +1,182 @@
+/*
+ * Copyright (c) 2023 Arm Limited. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package csolution
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// lockFileName is the name of the pack hash lockfile, written next to
+// InputFile, mirroring the convention of a package manager's lockfile
+// living beside its manifest.
+const lockFileName = "cbuild.lock.yml"
+
+// packRef identifies a single resolved CMSIS pack.
+type packRef struct {
+	Vendor  string
+	Pack    string
+	Version string
+}
+
+// id returns the canonical "Vendor::Pack@Version" form used as a
+// cbuild.lock.yml key.
+func (p packRef) id() string {
+	return fmt.Sprintf("%s::%s@%s", p.Vendor, p.Pack, p.Version)
+}
+
+// packIDPattern accepts both the "Vendor::Pack@Version" and
+// "Vendor::Pack:Version" forms csolution has used for pack identifiers.
+var packIDPattern = regexp.MustCompile(`^([^:]+)::([^:@]+)[:@](.+)$`)
+
+// parsePackID parses a single "Vendor::Pack@Version" (or "...:Version")
+// entry as reported by `csolution list packs`.
+func parsePackID(id string) (packRef, error) {
+	m := packIDPattern.FindStringSubmatch(strings.TrimSpace(id))
+	if m == nil {
+		return packRef{}, fmt.Errorf("invalid pack identifier %q", id)
+	}
+	return packRef{Vendor: m[1], Pack: m[2], Version: m[3]}, nil
+}
+
+// packFilePath returns the .pack file parsePackID's entry resolves to
+// under CMSIS_PACK_ROOT, following the <root>/<vendor>/<pack>/<version>
+// layout cpackget installs packs into.
+func (p packRef) packFilePath(packRoot string) string {
+	fileName := fmt.Sprintf("%s.%s.%s.pack", p.Vendor, p.Pack, p.Version)
+	return filepath.Join(packRoot, p.Vendor, p.Pack, p.Version, fileName)
+}
+
+// hashFile returns the lowercase hex-encoded SHA-256 digest of path.
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	digest := sha256.New()
+	if _, err := io.Copy(digest, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// lockFilePath returns the cbuild.lock.yml path for this builder's
+// InputFile.
+func (b CSolutionBuilder) lockFilePath() string {
+	return filepath.Join(filepath.Dir(b.InputFile), lockFileName)
+}
+
+// readLockFile loads an existing cbuild.lock.yml, or an empty map if
+// path doesn't exist.
+func readLockFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lock := map[string]string{}
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return lock, nil
+}
+
+// writeLockFile writes lock's entries, "Vendor::Pack@Version: sha256:<hex>",
+// to path.
+func writeLockFile(path string, lock map[string]string) error {
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// resolvedPackHashes resolves every pack InputFile requires to its
+// current SHA-256, keyed by "Vendor::Pack@Version".
+func (b CSolutionBuilder) resolvedPackHashes() (map[string]string, error) {
+	csolutionBin := b.InstallConfigs.Bin("csolution")
+	if _, err := os.Stat(csolutionBin); err != nil {
+		return nil, err
+	}
+
+	output, err := b.Runner.ExecuteCommand(csolutionBin, true, "list", "packs", "-s", b.InputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	packRoot := os.Getenv("CMSIS_PACK_ROOT")
+	hashes := map[string]string{}
+	for _, entry := range splitLines(output) {
+		ref, err := parsePackID(entry)
+		if err != nil {
+			return nil, err
+		}
+		hash, err := hashFile(ref.packFilePath(packRoot))
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash pack %s: %w", ref.id(), err)
+		}
+		hashes[ref.id()] = "sha256:" + hash
+	}
+	return hashes, nil
+}
+
+// Verify installs every pack InputFile requires and checks their content
+// hashes against cbuild.lock.yml. When the lockfile doesn't exist yet, or
+// Options.UpdateLock is set, it is (re)written from the currently
+// resolved hashes instead of being checked.
+func (b CSolutionBuilder) Verify() error {
+	if err := b.installMissingPacks(); err != nil {
+		return err
+	}
+
+	hashes, err := b.resolvedPackHashes()
+	if err != nil {
+		return err
+	}
+
+	lockPath := b.lockFilePath()
+	_, statErr := os.Stat(lockPath)
+	if b.Options.UpdateLock || os.IsNotExist(statErr) {
+		log.Infof("writing %s", lockPath)
+		return writeLockFile(lockPath, hashes)
+	}
+
+	lock, err := readLockFile(lockPath)
+	if err != nil {
+		return err
+	}
+
+	var mismatches []string
+	for pack, hash := range hashes {
+		locked, ok := lock[pack]
+		if !ok {
+			mismatches = append(mismatches, fmt.Sprintf("%s: not present in %s", pack, lockFileName))
+		} else if locked != hash {
+			mismatches = append(mismatches, fmt.Sprintf("%s: expected %s, got %s", pack, locked, hash))
+		}
+	}
+	if len(mismatches) > 0 {
+		sort.Strings(mismatches)
+		return fmt.Errorf("pack hash verification failed:\n%s", strings.Join(mismatches, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2023 Arm Limited. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package csolution
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// outputFormat normalizes Options.Output, defaulting to "text" so
+// callers that never set it keep the original line-oriented behavior.
+func outputFormat(format string) string {
+	if format == "" {
+		return "text"
+	}
+	return format
+}
+
+// marshalOutput renders doc as JSON or YAML according to format. Callers
+// only invoke this for non-"text" formats.
+func marshalOutput(format string, doc interface{}) (string, error) {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case "yaml":
+		data, err := yaml.Marshal(doc)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	default:
+		return "", fmt.Errorf("unsupported output format %q, expected one of text, json, yaml", format)
+	}
+}
+
+// contextRecord is the structured representation of a single context, as
+// emitted by ListContexts in JSON/YAML mode.
+type contextRecord struct {
+	Context    string `json:"context" yaml:"context"`
+	Project    string `json:"project" yaml:"project"`
+	BuildType  string `json:"build_type" yaml:"build_type"`
+	TargetType string `json:"target_type" yaml:"target_type"`
+	Toolchain  string `json:"toolchain" yaml:"toolchain"`
+}
+
+// contextNamePattern splits a context of the form
+// "<project>.<build-type>+<target-type>" into its components; either the
+// build type or the target type may be absent.
+var contextNamePattern = regexp.MustCompile(`^([^.+]+)(?:\.([^.+]*))?(?:\+(.*))?$`)
+
+// toContextRecord parses a single context name into a contextRecord,
+// attaching the toolchain csolution resolved for that specific context
+// (empty if it couldn't be resolved).
+func toContextRecord(context string, toolchain string) contextRecord {
+	record := contextRecord{Context: context, Toolchain: toolchain}
+	if m := contextNamePattern.FindStringSubmatch(context); m != nil {
+		record.Project = m[1]
+		record.BuildType = m[2]
+		record.TargetType = m[3]
+	}
+	return record
+}
+
+// toolchainRecord is the structured representation of a single
+// "<name>@<version>" toolchain entry.
+type toolchainRecord struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// toolchainNamePattern splits "AC6@6.18.0" into name and version.
+var toolchainNamePattern = regexp.MustCompile(`^(.+)@(.+)$`)
+
+// toToolchainRecord parses a single "<name>@<version>" toolchain entry.
+func toToolchainRecord(toolchain string) toolchainRecord {
+	if m := toolchainNamePattern.FindStringSubmatch(toolchain); m != nil {
+		return toolchainRecord{Name: m[1], Version: m[2]}
+	}
+	return toolchainRecord{Name: toolchain}
+}
+
+// toolRecord describes one host tool cbuild located, and how it was
+// found: Source is "install" for the toolbox's own tools and "PATH" for
+// the rest; no tool is reported with any other source.
+type toolRecord struct {
+	Name    string `json:"name" yaml:"name"`
+	Path    string `json:"path" yaml:"path"`
+	Version string `json:"version" yaml:"version"`
+	Source  string `json:"source" yaml:"source"`
+}
+
+// environmentDoc is the structured representation of `list environment`.
+type environmentDoc struct {
+	CmsisPackRoot     string       `json:"cmsis_pack_root" yaml:"cmsis_pack_root"`
+	CmsisCompilerRoot string       `json:"cmsis_compiler_root" yaml:"cmsis_compiler_root"`
+	CmsisBuildRoot    string       `json:"cmsis_build_root" yaml:"cmsis_build_root"`
+	Tools             []toolRecord `json:"tools" yaml:"tools"`
+}
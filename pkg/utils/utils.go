@@ -0,0 +1,91 @@
+/*
+ * Copyright (c) 2023 Arm Limited. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// Configurations holds the paths and extensions needed to locate the
+// CMSIS-Build tools (csolution, cbuildgen, cpackget) on the host.
+type Configurations struct {
+	BinPath      string
+	EtcPath      string
+	BinExtn      string
+	CompilerRoot string
+}
+
+// GetInstallConfigs resolves the install configuration from the
+// CMSIS_BUILD_ROOT environment variable. CMSIS_BUILD_ROOT itself (the
+// toolbox's bin directory) is expected to hold the csolution, cbuildgen
+// and cpackget executables, while CMSIS_BUILD_ROOT/../etc holds shared
+// configuration such as schemas.
+func GetInstallConfigs() (Configurations, error) {
+	buildRoot := os.Getenv("CMSIS_BUILD_ROOT")
+	if buildRoot == "" {
+		return Configurations{}, errors.New("CMSIS_BUILD_ROOT environment variable is not set")
+	}
+
+	binExtn := ""
+	if runtime.GOOS == "windows" {
+		binExtn = ".exe"
+	}
+
+	return Configurations{
+		BinPath:      buildRoot,
+		EtcPath:      filepath.Join(filepath.Dir(buildRoot), "etc"),
+		BinExtn:      binExtn,
+		CompilerRoot: os.Getenv("CMSIS_COMPILER_ROOT"),
+	}, nil
+}
+
+// Bin returns the full path to the named tool executable, honoring the
+// platform's binary extension (".exe" on Windows).
+func (c Configurations) Bin(name string) string {
+	return filepath.Join(c.BinPath, name+c.BinExtn)
+}
+
+// CommandRunner is the production implementation of builder.Runner: it
+// spawns the given program as a real OS process and returns its combined
+// stdout/stderr.
+type CommandRunner struct{}
+
+// ExecuteCommand runs program with args, streaming output to stdout
+// unless quiet is set, and always returns the captured output alongside
+// any execution error.
+func (r CommandRunner) ExecuteCommand(program string, quiet bool, args ...string) (string, error) {
+	cmd := exec.Command(program, args...)
+
+	var out bytes.Buffer
+	if quiet {
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+	} else {
+		cmd.Stdout = &multiWriter{w: &out, std: os.Stdout}
+		cmd.Stderr = &multiWriter{w: &out, std: os.Stderr}
+	}
+
+	err := cmd.Run()
+	return out.String(), err
+}
+
+// multiWriter mirrors writes to both a capture buffer and the real
+// stdout/stderr, so non-quiet runs are both visible and recorded.
+type multiWriter struct {
+	w   *bytes.Buffer
+	std *os.File
+}
+
+func (m *multiWriter) Write(p []byte) (int, error) {
+	m.w.Write(p)
+	return m.std.Write(p)
+}